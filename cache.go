@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// APP_CACHE_DIR directory of the where cache and config files are
+	// saved, under their respective XDG base directories.
+	APP_CACHE_DIR = "asadesuka"
+	// CACHE_TTL_ENV is the env var used to override how long a cached day
+	// is considered fresh. Accepts any value understood by
+	// time.ParseDuration, e.g. "1h", "30m".
+	CACHE_TTL_ENV = "ASA_CACHE_TTL"
+	// WINDOW_DAYS is the size of the rolling prefetch window: today plus
+	// the following days, so the tool keeps working offline for a week
+	// after one online run.
+	WINDOW_DAYS = 7
+	// prefetchWorkers caps how many days are fetched concurrently when
+	// refreshing a window.
+	prefetchWorkers = 4
+)
+
+// dayEntry is one day's sunrise/sunset answer, persisted as part of a
+// profileCache window.
+type dayEntry struct {
+	// Date is the calendar date this entry answers for, "YYYY-MM-DD" in
+	// the profile's tzid.
+	Date string `json:"date"`
+	// FetchedAt is when this entry was last confirmed fresh, either by a
+	// full answer or a 304-style "nothing changed" response.
+	FetchedAt time.Time `json:"fetched_at"`
+	// ETag is the provider's ETag response header, if any, sent back as
+	// If-None-Match on the next refresh.
+	ETag string `json:"etag,omitempty"`
+	// LastModified is the provider's Last-Modified response header, if
+	// any, sent back as If-Modified-Since on the next refresh.
+	LastModified string `json:"last_modified,omitempty"`
+	// Sunrise and Sunset are in UTC. Both are zero when AlwaysDay or
+	// AlwaysNight is set.
+	Sunrise time.Time `json:"sunrise"`
+	Sunset  time.Time `json:"sunset"`
+	// AlwaysDay and AlwaysNight record a provider's ErrAlwaysDay /
+	// ErrAlwaysNight answer, for locations in polar day or polar night.
+	AlwaysDay   bool `json:"always_day,omitempty"`
+	AlwaysNight bool `json:"always_night,omitempty"`
+}
+
+// profileCache is the rolling window of dayEntry values persisted per
+// location profile, under ~/.cache/asadesuka/<profile>.json.
+type profileCache struct {
+	// Key identifies the request this window answers, formatted as
+	// "lat|lng|tzid|provider". A mismatch means the window was fetched
+	// for a different location or provider and must be rebuilt.
+	Key string `json:"key"`
+	// Days maps "YYYY-MM-DD" to that day's entry.
+	Days map[string]*dayEntry `json:"days"`
+}
+
+// cacheKey builds the identity of a sun data request so a cached window is
+// invalidated the moment the caller points it at a different location or
+// provider.
+func cacheKey(lat, lng, tzid, provider string) string {
+	return fmt.Sprintf("%s|%s|%s|%s", lat, lng, tzid, provider)
+}
+
+// cacheFilePath returns the absolute path to profile's cache file, creating
+// APP_CACHE_DIR if it doesn't exist yet.
+func cacheFilePath(profile string) (string, error) {
+	if !validProfileName(profile) {
+		return "", fmt.Errorf("invalid profile name %q", profile)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cacheDir, APP_CACHE_DIR)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, profile+".json"), nil
+}
+
+// loadProfileCache loads profile's cache window. A missing cache file is not
+// an error: it just means there's nothing to load yet.
+func loadProfileCache(profile string) (*profileCache, error) {
+	path, err := cacheFilePath(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cache profileCache
+	if err := json.NewDecoder(file).Decode(&cache); err != nil {
+		return nil, err
+	}
+
+	return &cache, nil
+}
+
+// saveProfileCache saves profile's cache window into the cache directory.
+func saveProfileCache(profile string, cache *profileCache) error {
+	path, err := cacheFilePath(profile)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(cache)
+}
+
+// dayEntryTTL returns how long a dayEntry should be trusted without
+// contacting the provider again. ASA_CACHE_TTL, when set to a valid
+// duration, always wins. Otherwise a day's sunrise/sunset doesn't change
+// once fetched, so entries default to a full window's worth of trust.
+func dayEntryTTL() time.Duration {
+	if raw := os.Getenv(CACHE_TTL_ENV); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+
+	return WINDOW_DAYS * 24 * time.Hour
+}
+
+// isDayEntryValid reports whether entry can be used as-is, without
+// contacting the provider at all.
+func isDayEntryValid(entry *dayEntry) bool {
+	if entry == nil {
+		return false
+	}
+
+	return time.Since(entry.FetchedAt) < dayEntryTTL()
+}
+
+// fetchWindow resolves a dayEntry for every date in dates, reusing prev
+// entries that are still valid and fetching the rest from provider
+// concurrently through a small worker pool.
+func fetchWindow(provider SunProvider, lat, lng float64, tz *time.Location, dates []time.Time, prev map[string]*dayEntry) map[string]*dayEntry {
+	workers := prefetchWorkers
+	if len(dates) < workers {
+		workers = len(dates)
+	}
+
+	jobs := make(chan time.Time)
+	out := make(map[string]*dayEntry, len(dates))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for date := range jobs {
+				key := date.Format("2006-01-02")
+				entry, err := fetchDay(provider, lat, lng, tz, date, prev[key])
+				if err != nil {
+					fmt.Printf("Error fetching sun data for %s: %s\n", key, err.Error())
+					continue
+				}
+
+				mu.Lock()
+				out[key] = entry
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, date := range dates {
+		jobs <- date
+	}
+	close(jobs)
+	wg.Wait()
+
+	return out
+}
+
+// fetchDay resolves a single day's entry, reusing prev as-is if still valid,
+// using HTTP validators against prev if the provider supports them, and
+// falling back to prev (stale-if-error) if the provider fails outright.
+func fetchDay(provider SunProvider, lat, lng float64, tz *time.Location, date time.Time, prev *dayEntry) (*dayEntry, error) {
+	dateKey := date.Format("2006-01-02")
+
+	if isDayEntryValid(prev) {
+		return prev, nil
+	}
+
+	if vp, ok := provider.(validatingProvider); ok {
+		var etag, lastModified string
+		if prev != nil {
+			etag, lastModified = prev.ETag, prev.LastModified
+		}
+
+		sunrise, sunset, notModified, newETag, newLastModified, err := vp.OnWithValidators(date, lat, lng, tz, etag, lastModified)
+		if err == ErrAlwaysDay || err == ErrAlwaysNight {
+			return sentinelDayEntry(dateKey, err), nil
+		}
+		if err != nil {
+			if prev != nil {
+				return prev, nil
+			}
+			return nil, err
+		}
+		if notModified {
+			if prev == nil {
+				return nil, fmt.Errorf("provider reported not-modified for %s without a prior cache entry", dateKey)
+			}
+			prev.FetchedAt = time.Now()
+			return prev, nil
+		}
+
+		return &dayEntry{
+			Date:         dateKey,
+			FetchedAt:    time.Now(),
+			ETag:         newETag,
+			LastModified: newLastModified,
+			Sunrise:      sunrise,
+			Sunset:       sunset,
+		}, nil
+	}
+
+	sunrise, sunset, err := provider.On(date, lat, lng, tz)
+	if err == ErrAlwaysDay || err == ErrAlwaysNight {
+		return sentinelDayEntry(dateKey, err), nil
+	}
+	if err != nil {
+		if prev != nil {
+			return prev, nil
+		}
+		return nil, err
+	}
+
+	return &dayEntry{Date: dateKey, FetchedAt: time.Now(), Sunrise: sunrise, Sunset: sunset}, nil
+}
+
+// sentinelDayEntry builds the dayEntry recording a provider's ErrAlwaysDay /
+// ErrAlwaysNight answer.
+func sentinelDayEntry(dateKey string, sentinel error) *dayEntry {
+	return &dayEntry{
+		Date:        dateKey,
+		FetchedAt:   time.Now(),
+		AlwaysDay:   sentinel == ErrAlwaysDay,
+		AlwaysNight: sentinel == ErrAlwaysNight,
+	}
+}
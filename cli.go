@@ -0,0 +1,441 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Exit codes shared by every subcommand: 0 for daytime, 1 for nighttime, 2
+// for errors, so shell scripts can branch on $? directly.
+const (
+	exitDay   = 0
+	exitNight = 1
+	exitError = 2
+)
+
+// run dispatches to the subcommand named by args[0] and returns the process
+// exit code.
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage())
+		return exitError
+	}
+
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "is-day":
+		return runIsDay(rest)
+	case "today":
+		return runToday(rest)
+	case "next":
+		return runNext(rest)
+	case "watch":
+		return runWatch(rest)
+	case "profiles":
+		return runProfiles(rest)
+	case "-h", "--help", "help":
+		fmt.Println(usage())
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "asadesuka: unknown command %q\n\n%s\n", cmd, usage())
+		return exitError
+	}
+}
+
+func usage() string {
+	return `usage: asadesuka <command> [flags]
+
+commands:
+  is-day   print "true"/"false" for day/night, exit 0/1 to match
+  today    print {sunrise, sunset, now, is_day, tz} as JSON
+  next     print seconds until the next sunrise or sunset
+  watch    block until the next sunrise/sunset transition, then exit
+  profiles add/list/remove named locations (ASA_PROFILE selects one)
+
+flags (is-day/today/next/watch):
+  --lat <float>    latitude, overrides ASA_LAT / active profile
+  --lng <float>    longitude, overrides ASA_LNG / active profile
+  --tzid <string>  tzid, overrides ASA_TZID / active profile`
+}
+
+// locationFlags is the --lat/--lng/--tzid flag set shared by every
+// is-day/today/next/watch subcommand. Flags override ASA_LAT/ASA_LNG/
+// ASA_TZID, which in turn override the ASA_PROFILE-selected profile.
+type locationFlags struct {
+	fs   *flag.FlagSet
+	lat  *string
+	lng  *string
+	tzid *string
+}
+
+func newLocationFlags(name string) *locationFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	var profileLat, profileLng, profileTzid string
+	if p, ok := activeProfile(); ok {
+		profileLat = strconv.FormatFloat(p.Lat, 'f', -1, 64)
+		profileLng = strconv.FormatFloat(p.Lng, 'f', -1, 64)
+		profileTzid = p.Tzid
+	}
+
+	return &locationFlags{
+		fs:   fs,
+		lat:  fs.String("lat", firstNonEmpty(os.Getenv("ASA_LAT"), profileLat), "latitude, overrides ASA_LAT / active profile"),
+		lng:  fs.String("lng", firstNonEmpty(os.Getenv("ASA_LNG"), profileLng), "longitude, overrides ASA_LNG / active profile"),
+		tzid: fs.String("tzid", firstNonEmpty(os.Getenv("ASA_TZID"), profileTzid), "tzid, overrides ASA_TZID / active profile"),
+	}
+}
+
+// firstNonEmpty returns a, or b if a is empty.
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// parse parses args and returns the resolved lat/lng/tzid.
+func (lf *locationFlags) parse(args []string) (lat, lng float64, tzid string, err error) {
+	if err := lf.fs.Parse(args); err != nil {
+		return 0, 0, "", err
+	}
+
+	tzid = *lf.tzid
+	if tzid == "" {
+		// use default to UTC
+		tzid = "UTC"
+	}
+
+	lat, err = strconv.ParseFloat(*lf.lat, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid --lat %q: %w", *lf.lat, err)
+	}
+	lng, err = strconv.ParseFloat(*lf.lng, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid --lng %q: %w", *lf.lng, err)
+	}
+
+	return lat, lng, tzid, nil
+}
+
+// sunWindow ensures the active profile's rolling cache window covers today
+// through WINDOW_DAYS-1 days from now, refreshing it from the
+// ASA_PROVIDER-selected SunProvider if needed, and returns the window along
+// with "today" (in tz) so callers can look up both today's and tomorrow's
+// already-prefetched entries.
+func sunWindow(lat, lng float64, tzid string) (cache *profileCache, today time.Time, err error) {
+	tz, err := time.LoadLocation(tzid)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	provider := providerFromEnv()
+	key := cacheKey(
+		strconv.FormatFloat(lat, 'f', -1, 64),
+		strconv.FormatFloat(lng, 'f', -1, 64),
+		tzid,
+		provider.Name(),
+	)
+	profileName := activeProfileName()
+
+	cache, err = loadProfileCache(profileName)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if cache == nil || cache.Key != key {
+		cache = &profileCache{Key: key, Days: map[string]*dayEntry{}}
+	}
+
+	today = time.Now().In(tz)
+	dates := make([]time.Time, WINDOW_DAYS)
+	for i := range dates {
+		dates[i] = today.AddDate(0, 0, i)
+	}
+
+	needsFetch := false
+	for _, date := range dates {
+		if !isDayEntryValid(cache.Days[date.Format("2006-01-02")]) {
+			needsFetch = true
+			break
+		}
+	}
+
+	if needsFetch {
+		cache.Days = fetchWindow(provider, lat, lng, tz, dates, cache.Days)
+		if err := saveProfileCache(profileName, cache); err != nil {
+			fmt.Printf("Error saving sun data: %s\n", err.Error())
+		}
+	}
+
+	return cache, today, nil
+}
+
+// todayEntry looks up today's entry in cache.Days.
+func todayEntry(cache *profileCache, today time.Time) (*dayEntry, error) {
+	key := today.Format("2006-01-02")
+	entry, ok := cache.Days[key]
+	if !ok {
+		return nil, fmt.Errorf("no sun data for %s", key)
+	}
+	return entry, nil
+}
+
+// isDaytime reports whether it is currently daytime for entry.
+func isDaytime(entry *dayEntry) bool {
+	if entry.AlwaysDay {
+		return true
+	}
+	if entry.AlwaysNight {
+		return false
+	}
+
+	now := time.Now()
+	return now.After(entry.Sunrise) && now.Before(entry.Sunset)
+}
+
+// nextTransition returns the next sunrise or sunset after now, and whether
+// it is currently daytime. AlwaysDay/AlwaysNight entries return an error,
+// since a single day's window doesn't tell us when the polar day or night
+// actually ends. Once today's sunset has passed, the transition is
+// tomorrow's sunrise: that is read straight out of cache.Days, which
+// sunWindow already prefetched, rather than approximated with a fixed 24h
+// step that drifts from the real sunrise by minutes around solstices,
+// equinoxes, and at high latitudes.
+func nextTransition(cache *profileCache, today time.Time) (next time.Time, isDay bool, err error) {
+	entry, err := todayEntry(cache, today)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if entry.AlwaysDay || entry.AlwaysNight {
+		return time.Time{}, entry.AlwaysDay, errors.New("next: no sunrise/sunset transition during polar day/night")
+	}
+
+	now := time.Now()
+	if now.Before(entry.Sunrise) {
+		return entry.Sunrise, false, nil
+	}
+	if now.Before(entry.Sunset) {
+		return entry.Sunset, true, nil
+	}
+
+	tomorrowKey := today.AddDate(0, 0, 1).Format("2006-01-02")
+	if tomorrow, ok := cache.Days[tomorrowKey]; ok && !tomorrow.AlwaysDay && !tomorrow.AlwaysNight {
+		return tomorrow.Sunrise, false, nil
+	}
+
+	// Tomorrow isn't in the prefetched window (e.g. WINDOW_DAYS was
+	// lowered, or tomorrow is itself a polar transition); approximate.
+	return entry.Sunrise.AddDate(0, 0, 1), false, nil
+}
+
+func runIsDay(args []string) int {
+	lf := newLocationFlags("is-day")
+	lat, lng, tzid, err := lf.parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	cache, today, err := sunWindow(lat, lng, tzid)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	entry, err := todayEntry(cache, today)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	if isDaytime(entry) {
+		fmt.Println("true")
+		return exitDay
+	}
+	fmt.Println("false")
+	return exitNight
+}
+
+// todayOutput is what `asadesuka today` emits as JSON, for scripting.
+type todayOutput struct {
+	Sunrise time.Time `json:"sunrise"`
+	Sunset  time.Time `json:"sunset"`
+	Now     time.Time `json:"now"`
+	IsDay   bool      `json:"is_day"`
+	Tz      string    `json:"tz"`
+}
+
+func runToday(args []string) int {
+	lf := newLocationFlags("today")
+	lat, lng, tzid, err := lf.parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	cache, today, err := sunWindow(lat, lng, tzid)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	entry, err := todayEntry(cache, today)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	out := todayOutput{
+		Sunrise: entry.Sunrise,
+		Sunset:  entry.Sunset,
+		Now:     time.Now().UTC(),
+		IsDay:   isDaytime(entry),
+		Tz:      tzid,
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	if out.IsDay {
+		return exitDay
+	}
+	return exitNight
+}
+
+func runNext(args []string) int {
+	lf := newLocationFlags("next")
+	lat, lng, tzid, err := lf.parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	cache, today, err := sunWindow(lat, lng, tzid)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	next, isDay, err := nextTransition(cache, today)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	fmt.Println(int(time.Until(next).Seconds()))
+	if isDay {
+		return exitDay
+	}
+	return exitNight
+}
+
+func runWatch(args []string) int {
+	lf := newLocationFlags("watch")
+	lat, lng, tzid, err := lf.parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	cache, today, err := sunWindow(lat, lng, tzid)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	next, wasDay, err := nextTransition(cache, today)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	time.Sleep(time.Until(next))
+
+	if wasDay {
+		fmt.Println("false")
+		return exitNight
+	}
+	fmt.Println("true")
+	return exitDay
+}
+
+func runProfiles(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: asadesuka profiles <add|list|remove> ...")
+		return exitError
+	}
+
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "add":
+		return runProfilesAdd(rest)
+	case "list":
+		return runProfilesList(rest)
+	case "remove":
+		return runProfilesRemove(rest)
+	default:
+		fmt.Fprintf(os.Stderr, "asadesuka: unknown profiles subcommand %q\n", cmd)
+		return exitError
+	}
+}
+
+func runProfilesAdd(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: asadesuka profiles add <name> --lat <float> --lng <float> [--tzid <string>]")
+		return exitError
+	}
+	name, rest := args[0], args[1:]
+
+	fs := flag.NewFlagSet("profiles add", flag.ContinueOnError)
+	lat := fs.Float64("lat", 0, "latitude")
+	lng := fs.Float64("lng", 0, "longitude")
+	tzid := fs.String("tzid", "UTC", "tzid")
+	if err := fs.Parse(rest); err != nil {
+		return exitError
+	}
+
+	if err := addProfile(name, *lat, *lng, *tzid); err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	fmt.Printf("saved profile %q\n", name)
+	return exitDay
+}
+
+func runProfilesList(args []string) int {
+	profiles, err := loadProfiles()
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	for _, p := range profiles {
+		fmt.Printf("%s\tlat=%g\tlng=%g\ttzid=%s\n", p.Name, p.Lat, p.Lng, p.Tzid)
+	}
+	return exitDay
+}
+
+func runProfilesRemove(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: asadesuka profiles remove <name>")
+		return exitError
+	}
+
+	if err := removeProfile(args[0]); err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	fmt.Printf("removed profile %q\n", args[0])
+	return exitDay
+}
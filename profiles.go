@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PROFILE_ENV selects the active location profile, both for the cache
+// window's filename and as the fallback for --lat/--lng/--tzid.
+const PROFILE_ENV = "ASA_PROFILE"
+
+// defaultProfileName names the cache window used when ASA_PROFILE is unset.
+const defaultProfileName = "default"
+
+// validProfileName reports whether name is safe to use as a cache/config
+// filename component. Profile names come from `profiles add` and
+// ASA_PROFILE, both outside our control, so path separators and ".."
+// components are rejected to keep them confined to APP_CACHE_DIR.
+func validProfileName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
+
+// profile is a named location, persisted so users can query "is it daytime
+// at my parents' house" without re-exporting env vars.
+type profile struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+	Tzid string  `json:"tzid"`
+}
+
+// profilesFilePath returns the absolute path to the profiles config file,
+// creating its directory if needed.
+func profilesFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(configDir, APP_CACHE_DIR)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "profiles.json"), nil
+}
+
+// loadProfiles reads the persisted profiles. A missing file means there are
+// none yet.
+func loadProfiles() ([]profile, error) {
+	path, err := profilesFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var profiles []profile
+	if err := json.NewDecoder(file).Decode(&profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// saveProfiles persists profiles, replacing whatever was there before.
+func saveProfiles(profiles []profile) error {
+	path, err := profilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(profiles)
+}
+
+// activeProfile returns the profile named by ASA_PROFILE, if any.
+func activeProfile() (*profile, bool) {
+	name := os.Getenv(PROFILE_ENV)
+	if name == "" {
+		return nil, false
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return nil, false
+	}
+
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// activeProfileName returns ASA_PROFILE, defaulting to defaultProfileName,
+// for use as the cache window's filename.
+func activeProfileName() string {
+	if name := os.Getenv(PROFILE_ENV); name != "" {
+		return name
+	}
+	return defaultProfileName
+}
+
+// addProfile persists name as a location profile, replacing any existing
+// profile with the same name.
+func addProfile(name string, lat, lng float64, tzid string) error {
+	if !validProfileName(name) {
+		return fmt.Errorf("invalid profile name %q: must not contain path separators or be \"..\"", name)
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	for i := range profiles {
+		if profiles[i].Name == name {
+			profiles[i] = profile{Name: name, Lat: lat, Lng: lng, Tzid: tzid}
+			return saveProfiles(profiles)
+		}
+	}
+
+	profiles = append(profiles, profile{Name: name, Lat: lat, Lng: lng, Tzid: tzid})
+	return saveProfiles(profiles)
+}
+
+// removeProfile deletes the profile named name.
+func removeProfile(name string) error {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	kept := profiles[:0]
+	for _, p := range profiles {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(profiles) {
+		return fmt.Errorf("no such profile: %s", name)
+	}
+
+	return saveProfiles(kept)
+}
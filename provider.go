@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+// PROVIDER_ENV selects which SunProvider backs the tool.
+const PROVIDER_ENV = "ASA_PROVIDER"
+
+// httpClient is shared by every HTTP-backed provider. A short timeout keeps
+// a dead network (captive portal, dropped packets, slow DNS) from hanging
+// for the OS TCP timeout instead of degrading to the stale-if-error cache
+// path within a few seconds.
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+const (
+	// ProviderSunriseSunset fetches from api.sunrise-sunset.org (default).
+	ProviderSunriseSunset = "sunrise-sunset"
+	// ProviderOpenMeteo fetches from the Open-Meteo forecast API. No API
+	// key required.
+	ProviderOpenMeteo = "open-meteo"
+	// ProviderOffline computes sunrise/sunset locally using the NOAA
+	// Solar Position Algorithm. Works with zero network access.
+	ProviderOffline = "offline"
+)
+
+var (
+	// ErrAlwaysDay is returned by a SunProvider when the location is in
+	// polar day: the sun never sets.
+	ErrAlwaysDay = errors.New("polar day: sun never sets")
+	// ErrAlwaysNight is returned by a SunProvider when the location is in
+	// polar night: the sun never rises.
+	ErrAlwaysNight = errors.New("polar night: sun never rises")
+)
+
+// SunProvider computes a given day's sunrise and sunset for a location, in
+// UTC.
+type SunProvider interface {
+	// Name identifies the provider. It is stored alongside cached data so
+	// switching providers never hands back another provider's answer.
+	Name() string
+	// On returns date's sunrise and sunset, in UTC, for the given
+	// coordinates. tz is used to determine what "date" and "local time"
+	// mean for that location. At latitudes experiencing polar day or
+	// polar night, On returns ErrAlwaysDay or ErrAlwaysNight instead.
+	On(date time.Time, lat, lng float64, tz *time.Location) (sunrise, sunset time.Time, err error)
+}
+
+// validatingProvider is implemented by providers that can use HTTP
+// conditional-request validators (ETag / Last-Modified) to avoid re-fetching
+// data that hasn't changed.
+type validatingProvider interface {
+	SunProvider
+	// OnWithValidators behaves like On, but passes along validators from a
+	// previous response. notModified is true when the server confirmed
+	// the previous answer is still correct, in which case sunrise/sunset
+	// should be ignored by the caller.
+	OnWithValidators(date time.Time, lat, lng float64, tz *time.Location, etag, lastModified string) (sunrise, sunset time.Time, notModified bool, newETag, newLastModified string, err error)
+}
+
+// providerFromEnv resolves the SunProvider selected by ASA_PROVIDER,
+// defaulting to the sunrise-sunset.org backed provider.
+func providerFromEnv() SunProvider {
+	switch os.Getenv(PROVIDER_ENV) {
+	case ProviderOpenMeteo:
+		return openMeteoProvider{}
+	case ProviderOffline:
+		return offlineProvider{}
+	default:
+		return sunriseSunsetProvider{}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// offlineProvider is a SunProvider that needs no network at all: it computes
+// sunrise/sunset from the NOAA Solar Position Algorithm.
+type offlineProvider struct{}
+
+func (offlineProvider) Name() string { return ProviderOffline }
+
+func (offlineProvider) On(date time.Time, lat, lng float64, tz *time.Location) (time.Time, time.Time, error) {
+	now := date.In(tz)
+
+	// Julian day, used to derive the sun's position for the target date.
+	julianDay := float64(now.UTC().Unix())/86400.0 + 2440587.5
+	n := julianDay - 2451545.0 + 0.0008
+
+	meanLongitude := math.Mod(280.460+0.9856474*n, 360)
+	meanLongitudeRad := meanLongitude * math.Pi / 180
+	meanAnomaly := math.Mod(357.528+0.9856003*n, 360) * math.Pi / 180
+	eclipticLongitude := (meanLongitude + 1.915*math.Sin(meanAnomaly) + 0.020*math.Sin(2*meanAnomaly)) * math.Pi / 180
+
+	const obliquity = 23.439 * math.Pi / 180
+	declination := math.Asin(math.Sin(obliquity) * math.Sin(eclipticLongitude))
+
+	// Equation of time, in minutes, from the Julian day.
+	y := math.Tan(obliquity/2) * math.Tan(obliquity/2)
+	const eccentricity = 0.0167
+	equationOfTime := 4 * (y*math.Sin(2*meanLongitudeRad) - 2*eccentricity*math.Sin(meanAnomaly) +
+		4*eccentricity*y*math.Sin(meanAnomaly)*math.Cos(2*meanLongitudeRad) -
+		0.5*y*y*math.Sin(4*meanLongitudeRad) - 1.25*eccentricity*eccentricity*math.Sin(2*meanAnomaly)) * 180 / math.Pi
+
+	latRad := lat * math.Pi / 180
+	cosHourAngle := (math.Sin(-0.833*math.Pi/180) - math.Sin(latRad)*math.Sin(declination)) /
+		(math.Cos(latRad) * math.Cos(declination))
+
+	if cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, ErrAlwaysNight
+	}
+	if cosHourAngle < -1 {
+		return time.Time{}, time.Time{}, ErrAlwaysDay
+	}
+
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+
+	_, offsetSeconds := now.Zone()
+	tzOffsetMinutes := float64(offsetSeconds) / 60
+	solarNoonMinutes := 720 - 4*lng - equationOfTime + tzOffsetMinutes
+
+	sunriseMinutes := solarNoonMinutes - hourAngle*4
+	sunsetMinutes := solarNoonMinutes + hourAngle*4
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+	sunrise := midnight.Add(time.Duration(sunriseMinutes * float64(time.Minute))).UTC()
+	sunset := midnight.Add(time.Duration(sunsetMinutes * float64(time.Minute))).UTC()
+
+	return sunrise, sunset, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestOfflineProviderOn checks the NOAA SPA arithmetic against a small table
+// of published sunrise/sunset times. The tolerance is generous because the
+// implementation uses a truncated equation-of-time series, not
+// full-precision ephemeris data.
+func TestOfflineProviderOn(t *testing.T) {
+	cases := []struct {
+		name        string
+		lat, lng    float64
+		tzid        string
+		date        string // YYYY-MM-DD, in tzid
+		wantSunrise string // HH:MM, in tzid
+		wantSunset  string // HH:MM, in tzid
+		tolerance   time.Duration
+	}{
+		{
+			name:        "san francisco spring equinox",
+			lat:         37.7749,
+			lng:         -122.4194,
+			tzid:        "America/Los_Angeles",
+			date:        "2024-03-20",
+			wantSunrise: "07:06",
+			wantSunset:  "19:14",
+			tolerance:   10 * time.Minute,
+		},
+		{
+			name:        "tokyo midsummer",
+			lat:         35.6762,
+			lng:         139.6503,
+			tzid:        "Asia/Tokyo",
+			date:        "2024-07-01",
+			wantSunrise: "04:30",
+			wantSunset:  "19:01",
+			tolerance:   5 * time.Minute,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tz, err := time.LoadLocation(c.tzid)
+			if err != nil {
+				t.Fatalf("LoadLocation(%q): %v", c.tzid, err)
+			}
+
+			date, err := time.ParseInLocation("2006-01-02", c.date, tz)
+			if err != nil {
+				t.Fatalf("parse date: %v", err)
+			}
+
+			sunrise, sunset, err := (offlineProvider{}).On(date, c.lat, c.lng, tz)
+			if err != nil {
+				t.Fatalf("On() error = %v", err)
+			}
+
+			wantSunrise, err := time.ParseInLocation("2006-01-02 15:04", c.date+" "+c.wantSunrise, tz)
+			if err != nil {
+				t.Fatalf("parse want sunrise: %v", err)
+			}
+			wantSunset, err := time.ParseInLocation("2006-01-02 15:04", c.date+" "+c.wantSunset, tz)
+			if err != nil {
+				t.Fatalf("parse want sunset: %v", err)
+			}
+
+			if d := absDuration(sunrise.Sub(wantSunrise)); d > c.tolerance {
+				t.Errorf("sunrise = %s, want %s +/- %s, off by %s",
+					sunrise.In(tz).Format("15:04:05"), wantSunrise.Format("15:04:05"), c.tolerance, d)
+			}
+			if d := absDuration(sunset.Sub(wantSunset)); d > c.tolerance {
+				t.Errorf("sunset = %s, want %s +/- %s, off by %s",
+					sunset.In(tz).Format("15:04:05"), wantSunset.Format("15:04:05"), c.tolerance, d)
+			}
+		})
+	}
+}
+
+// TestOfflineProviderPolar checks the polar day/night sentinels at a high
+// latitude on the solstices, where the hour angle's acos argument falls
+// outside [-1, 1].
+func TestOfflineProviderPolar(t *testing.T) {
+	const lat, lng = 78.2232, 15.6267 // Svalbard
+
+	cases := []struct {
+		name string
+		date time.Time
+		want error
+	}{
+		{"polar day at summer solstice", time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC), ErrAlwaysDay},
+		{"polar night at winter solstice", time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC), ErrAlwaysNight},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := (offlineProvider{}).On(c.date, lat, lng, time.UTC)
+			if err != c.want {
+				t.Errorf("On() error = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
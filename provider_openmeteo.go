@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// openMeteoAPIURL is the Open-Meteo forecast endpoint. It requires no API
+// key.
+const openMeteoAPIURL = "https://api.open-meteo.com/v1/forecast"
+
+// openMeteoTimeLayout is the format Open-Meteo uses for daily sunrise/sunset
+// values: local time, no UTC offset, e.g. "2024-05-01T06:12".
+const openMeteoTimeLayout = "2006-01-02T15:04"
+
+// openMeteoProvider is a SunProvider backed by Open-Meteo's daily
+// sunrise/sunset forecast.
+type openMeteoProvider struct{}
+
+func (openMeteoProvider) Name() string { return ProviderOpenMeteo }
+
+func (openMeteoProvider) On(date time.Time, lat, lng float64, tz *time.Location) (time.Time, time.Time, error) {
+	dateStr := date.In(tz).Format("2006-01-02")
+
+	params := url.Values{}
+	params.Add("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Add("longitude", strconv.FormatFloat(lng, 'f', -1, 64))
+	params.Add("daily", "sunrise,sunset")
+	params.Add("timezone", tz.String())
+	params.Add("start_date", dateStr)
+	params.Add("end_date", dateStr)
+	apiUrl := fmt.Sprintf("%s?%s", openMeteoAPIURL, params.Encode())
+
+	resp, err := httpClient.Get(apiUrl)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, time.Time{}, fmt.Errorf("open-meteo api returned status %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Daily struct {
+			Sunrise []string `json:"sunrise"`
+			Sunset  []string `json:"sunset"`
+		} `json:"daily"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if len(data.Daily.Sunrise) == 0 || len(data.Daily.Sunset) == 0 {
+		return time.Time{}, time.Time{}, errors.New("open-meteo response is missing sunrise/sunset")
+	}
+
+	sunrise, err := time.ParseInLocation(openMeteoTimeLayout, data.Daily.Sunrise[0], tz)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	sunset, err := time.ParseInLocation(openMeteoTimeLayout, data.Daily.Sunset[0], tz)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return sunrise.UTC(), sunset.UTC(), nil
+}
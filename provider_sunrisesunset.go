@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sunriseSunsetAPIURL is the url of the api the sunrise/sunset data comes
+// from.
+const sunriseSunsetAPIURL = "https://api.sunrise-sunset.org/json"
+
+// sunriseSunsetProvider is the original SunProvider backed by
+// sunrise-sunset.org.
+type sunriseSunsetProvider struct{}
+
+func (sunriseSunsetProvider) Name() string { return ProviderSunriseSunset }
+
+func (p sunriseSunsetProvider) On(date time.Time, lat, lng float64, tz *time.Location) (time.Time, time.Time, error) {
+	sunrise, sunset, _, _, _, err := p.OnWithValidators(date, lat, lng, tz, "", "")
+	return sunrise, sunset, err
+}
+
+func (sunriseSunsetProvider) OnWithValidators(date time.Time, lat, lng float64, tz *time.Location, etag, lastModified string) (sunrise, sunset time.Time, notModified bool, newETag, newLastModified string, err error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Add("lng", strconv.FormatFloat(lng, 'f', -1, 64))
+	params.Add("tzid", tz.String())
+	params.Add("date", date.In(tz).Format("2006-01-02"))
+	params.Add("formatted", "0")
+	apiUrl := fmt.Sprintf("%s?%s", sunriseSunsetAPIURL, params.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return time.Time{}, time.Time{}, true, etag, lastModified, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, time.Time{}, false, "", "", fmt.Errorf("sunrise-sunset api returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+
+	var data struct {
+		Results struct {
+			// Sunrise is a time.RFC3339 format date string.
+			Sunrise string `json:"sunrise"`
+			// Sunset is a time.RFC3339 format date string.
+			Sunset string `json:"sunset"`
+		} `json:"results"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+
+	if strings.ToLower(data.Status) != "ok" {
+		return time.Time{}, time.Time{}, false, "", "", errors.New("Failed to fetch new sun data.")
+	}
+
+	sunrise, err = time.Parse(time.RFC3339, data.Results.Sunrise)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+	sunset, err = time.Parse(time.RFC3339, data.Results.Sunset)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, "", "", err
+	}
+
+	return sunrise, sunset, false, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}